@@ -0,0 +1,207 @@
+package gosql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// defaultBatchSize bounds how many rows go into a single multi-row
+// statement when TableConfig.BatchSize isn't set, keeping generated
+// queries under typical driver/placeholder and max_allowed_packet limits.
+const defaultBatchSize = 500
+
+// InsertMany inserts a slice of row pointers of a registered type with
+// one multi-row INSERT per batch instead of one Exec per row.
+func (o *orm) InsertMany(rows interface{}) (ids []int64, err error) {
+	return o.InsertManyContext(context.Background(), rows)
+}
+
+func (o *orm) InsertManyContext(ctx context.Context, rows interface{}) (ids []int64, err error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, ErrorTypeNotSupported
+	}
+	if v.Len() == 0 {
+		return nil, nil
+	}
+	cfg, err := o.getTableConfig(v.Index(0).Interface())
+	if err != nil {
+		return nil, err
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for start := 0; start < v.Len(); start += batchSize {
+		end := start + batchSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+		chunkIDs, err := o.insertChunk(ctx, cfg, v.Slice(start, end))
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, chunkIDs...)
+	}
+	return ids, nil
+}
+
+func (o *orm) insertChunk(ctx context.Context, cfg *TableConfig, chunk reflect.Value) ([]int64, error) {
+	n := chunk.Len()
+	hooks := o.rowHooks(chunk.Index(0).Interface())
+	now := time.Now()
+	var insertKeys []string
+	var valueQuery string
+	args := []interface{}{}
+	for i := 0; i < n; i++ {
+		keys, values, _ := o.extractRow(chunk.Index(i).Interface())
+		for j, key := range keys {
+			switch key {
+			case hooks.createdCol, hooks.updatedCol:
+				values[j] = now
+			case hooks.versionCol:
+				values[j] = int64(1)
+			}
+		}
+		rowQuery := ""
+		for j, key := range keys {
+			if cfg.AutoIncrement && cfg.PrimaryKey == key {
+				continue
+			}
+			if i == 0 {
+				insertKeys = append(insertKeys, key)
+			}
+			rowQuery += cfg.dialect.Placeholder(len(args)+1) + ","
+			args = append(args, values[j])
+		}
+		valueQuery += "(" + strings.TrimSuffix(rowQuery, ",") + "),"
+	}
+	valueQuery = strings.TrimSuffix(valueQuery, ",")
+	colQuery := ""
+	for _, key := range insertKeys {
+		colQuery += cfg.dialect.Quote(key) + ","
+	}
+	colQuery = strings.TrimSuffix(colQuery, ",")
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		cfg.dialect.Quote(cfg.TableName),
+		colQuery,
+		valueQuery,
+	)
+	if !cfg.AutoIncrement {
+		if _, err := cfg.db.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		return make([]int64, n), nil
+	}
+	return cfg.dialect.GenerateIDs(ctx, cfg.db, query, args, cfg.PrimaryKey, n)
+}
+
+// UpdateMany updates a slice of row pointers of a registered type with
+// one CASE-based bulk UPDATE per batch instead of one Exec per row.
+func (o *orm) UpdateMany(rows interface{}) (err error) {
+	return o.UpdateManyContext(context.Background(), rows)
+}
+
+func (o *orm) UpdateManyContext(ctx context.Context, rows interface{}) (err error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return ErrorTypeNotSupported
+	}
+	if v.Len() == 0 {
+		return nil
+	}
+	cfg, err := o.getTableConfig(v.Index(0).Interface())
+	if err != nil {
+		return err
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	for start := 0; start < v.Len(); start += batchSize {
+		end := start + batchSize
+		if end > v.Len() {
+			end = v.Len()
+		}
+		if err := o.updateChunk(ctx, cfg, v.Slice(start, end)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *orm) updateChunk(ctx context.Context, cfg *TableConfig, chunk reflect.Value) error {
+	n := chunk.Len()
+	hooks := o.rowHooks(chunk.Index(0).Interface())
+	now := time.Now()
+	rowValues := make([]map[string]interface{}, n)
+	oldVersions := make([]interface{}, n)
+	keys, _, _ := o.extractRow(chunk.Index(0).Interface())
+	for i := 0; i < n; i++ {
+		rowKeys, values, _ := o.extractRow(chunk.Index(i).Interface())
+		m := make(map[string]interface{}, len(rowKeys))
+		for j, key := range rowKeys {
+			switch {
+			case len(hooks.updatedCol) > 0 && key == hooks.updatedCol:
+				values[j] = now
+			case hooks.hasVersion() && key == hooks.versionCol:
+				oldVersions[i] = values[j]
+				values[j] = bumpVersion(values[j])
+			}
+			m[key] = values[j]
+		}
+		rowValues[i] = m
+	}
+	args := []interface{}{}
+	setQuery := ""
+	for _, key := range keys {
+		if key == cfg.PrimaryKey {
+			continue
+		}
+		caseQuery := fmt.Sprintf("%s=CASE %s", cfg.dialect.Quote(key), cfg.dialect.Quote(cfg.PrimaryKey))
+		for i := 0; i < n; i++ {
+			caseQuery += fmt.Sprintf(" WHEN %s THEN %s", cfg.dialect.Placeholder(len(args)+1), cfg.dialect.Placeholder(len(args)+2))
+			args = append(args, rowValues[i][cfg.PrimaryKey], rowValues[i][key])
+		}
+		caseQuery += " END"
+		setQuery += caseQuery + ","
+	}
+	setQuery = strings.TrimSuffix(setQuery, ",")
+	wherePairs := make([]string, n)
+	for i := 0; i < n; i++ {
+		if hooks.hasVersion() {
+			wherePairs[i] = fmt.Sprintf("(%s=%s AND %s=%s)",
+				cfg.dialect.Quote(cfg.PrimaryKey), cfg.dialect.Placeholder(len(args)+1),
+				cfg.dialect.Quote(hooks.versionCol), cfg.dialect.Placeholder(len(args)+2),
+			)
+			args = append(args, rowValues[i][cfg.PrimaryKey], oldVersions[i])
+		} else {
+			wherePairs[i] = fmt.Sprintf("%s=%s", cfg.dialect.Quote(cfg.PrimaryKey), cfg.dialect.Placeholder(len(args)+1))
+			args = append(args, rowValues[i][cfg.PrimaryKey])
+		}
+	}
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s",
+		cfg.dialect.Quote(cfg.TableName),
+		setQuery,
+		strings.Join(wherePairs, " OR "),
+	)
+	result, err := cfg.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if hooks.hasVersion() {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected < int64(n) {
+			return ErrorStaleObject
+		}
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package gosql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// paramString renders params as "k=v" pairs joined by sep, in a
+// deterministic (sorted by key) order.
+func paramString(params map[string]string, sep string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, params[k])
+	}
+	return strings.Join(pairs, sep)
+}
+
+// Dialect abstracts the per-backend SQL differences: DSN construction,
+// identifier quoting, and placeholder rendering. Register additional
+// backends with RegisterDialect without modifying this package.
+type Dialect interface {
+	// Name returns the driver name passed to sql.Open.
+	Name() string
+	// DSN builds the data source name from a TableConfig.
+	DSN(cfg *TableConfig) string
+	// Quote wraps a raw identifier (table or column name) the way the
+	// backend expects it in generated SQL.
+	Quote(identifier string) string
+	// Placeholder renders the parameter placeholder for the n-th
+	// (1-indexed) argument in a query.
+	Placeholder(n int) string
+	// AppendParams merges extra connection parameters (e.g. from
+	// TableConfig.Params) into a DSN already built by DSN.
+	AppendParams(dsn string, params map[string]string) string
+	// GenerateIDs executes query (an INSERT INTO table (cols) VALUES
+	// (...),(...) statement with n row groups) via exec and returns the
+	// generated primaryKey value for each inserted row, in row order.
+	// Backends differ on how generated ids are recovered: MySQL and
+	// SQLite hand them back through sql.Result.LastInsertId, while
+	// Postgres and MSSQL need the statement itself rewritten to report
+	// them, so this is a dialect-level hook rather than shared logic.
+	GenerateIDs(ctx context.Context, exec executor, query string, args []interface{}, primaryKey string, n int) ([]int64, error)
+	// AutoIncrementModifier returns the column-definition syntax AutoMigrate
+	// inserts immediately after a server-generated primary key's type
+	// (e.g. MySQL's "AUTO_INCREMENT"), or "" if the dialect instead marks
+	// it some other way (see AutoIncrementPrimaryKeySuffix).
+	AutoIncrementModifier() string
+	// AutoIncrementPrimaryKeySuffix returns the column-definition syntax
+	// AutoMigrate appends after PRIMARY KEY for a server-generated
+	// primary key (SQLite's AUTOINCREMENT, which must follow PRIMARY KEY
+	// rather than the column type), or "" if not applicable.
+	AutoIncrementPrimaryKeySuffix() string
+	// TimestampType returns the column type the migrations bookkeeping
+	// table uses to store its applied_at time.Time. AutoMigrate does not
+	// use it: its column types come from the sql tags (TEXT by default).
+	TimestampType() string
+	// CreateTableStatement returns the full CREATE TABLE statement for
+	// tableName given its column definitions. MySQL/Postgres/SQLite
+	// support CREATE TABLE IF NOT EXISTS; MSSQL's grammar has no such
+	// clause, so it's wrapped in an existence check against sys.tables.
+	CreateTableStatement(tableName, colDefs string) string
+	// SupportsIndexIfNotExists reports whether CREATE INDEX IF NOT
+	// EXISTS is valid syntax for this dialect (Postgres, SQLite). MySQL
+	// and MSSQL support neither, so callers must omit it and instead
+	// tolerate a duplicate-index error as a no-op.
+	SupportsIndexIfNotExists() bool
+	// AddColumnClause returns the clause AutoMigrate uses to introduce a
+	// column via ALTER TABLE (MySQL/Postgres/SQLite's "ADD COLUMN";
+	// MSSQL's grammar takes just "ADD").
+	AddColumnClause() string
+}
+
+var (
+	dialectLock sync.RWMutex
+	dialectMap  = make(map[string]Dialect)
+)
+
+// RegisterDialect makes a Dialect available by name for use in
+// TableConfig.Driver. It is typically called from an init func.
+func RegisterDialect(name string, dialect Dialect) {
+	dialectLock.Lock()
+	defer dialectLock.Unlock()
+	dialectMap[name] = dialect
+}
+
+func getDialect(name string) (Dialect, error) {
+	dialectLock.RLock()
+	defer dialectLock.RUnlock()
+	dialect, exists := dialectMap[name]
+	if !exists {
+		return nil, ErrorDialectNotSupported
+	}
+	return dialect, nil
+}
+
+func init() {
+	RegisterDialect("mysql", &mysqlDialect{})
+	RegisterDialect("postgres", &postgresDialect{})
+	RegisterDialect("sqlite3", &sqliteDialect{})
+	RegisterDialect("mssql", &mssqlDialect{})
+}
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) Name() string { return "mysql" }
+
+func (d *mysqlDialect) DSN(cfg *TableConfig) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
+		cfg.Username,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.DBName,
+	)
+}
+
+func (d *mysqlDialect) Quote(identifier string) string {
+	return fmt.Sprintf("`%s`", identifier)
+}
+
+func (d *mysqlDialect) Placeholder(n int) string { return "?" }
+
+func (d *mysqlDialect) AppendParams(dsn string, params map[string]string) string {
+	if len(params) == 0 {
+		return dsn
+	}
+	return fmt.Sprintf("%s?%s", dsn, paramString(params, "&"))
+}
+
+// GenerateIDs relies on AUTO_INCREMENT: MySQL's LastInsertId reports the
+// id assigned to the first row of a multi-row INSERT, with the rest
+// assigned sequentially.
+func (d *mysqlDialect) GenerateIDs(ctx context.Context, exec executor, query string, args []interface{}, primaryKey string, n int) ([]int64, error) {
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	firstID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = firstID + int64(i)
+	}
+	return ids, nil
+}
+
+func (d *mysqlDialect) AutoIncrementModifier() string        { return "AUTO_INCREMENT" }
+func (d *mysqlDialect) AutoIncrementPrimaryKeySuffix() string { return "" }
+func (d *mysqlDialect) TimestampType() string                { return "DATETIME" }
+
+func (d *mysqlDialect) CreateTableStatement(tableName, colDefs string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", d.Quote(tableName), colDefs)
+}
+
+// SupportsIndexIfNotExists is false: MySQL's CREATE INDEX has no IF NOT
+// EXISTS option.
+func (d *mysqlDialect) SupportsIndexIfNotExists() bool { return false }
+func (d *mysqlDialect) AddColumnClause() string        { return "ADD COLUMN" }
+
+type postgresDialect struct{}
+
+func (d *postgresDialect) Name() string { return "postgres" }
+
+func (d *postgresDialect) DSN(cfg *TableConfig) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		cfg.Host,
+		cfg.Port,
+		cfg.Username,
+		cfg.Password,
+		cfg.DBName,
+	)
+}
+
+func (d *postgresDialect) Quote(identifier string) string {
+	return fmt.Sprintf("%q", identifier)
+}
+
+func (d *postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+func (d *postgresDialect) AppendParams(dsn string, params map[string]string) string {
+	if len(params) == 0 {
+		return dsn
+	}
+	return fmt.Sprintf("%s %s", dsn, paramString(params, " "))
+}
+
+// GenerateIDs appends a RETURNING clause: lib/pq's sql.Result never
+// supports LastInsertId, so ids have to come back as query result rows.
+func (d *postgresDialect) GenerateIDs(ctx context.Context, exec executor, query string, args []interface{}, primaryKey string, n int) ([]int64, error) {
+	query = fmt.Sprintf("%s RETURNING %s", query, d.Quote(primaryKey))
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AutoIncrementModifier uses GENERATED ALWAYS AS IDENTITY rather than
+// the legacy SERIAL pseudo-type, since it has to directly follow the
+// column type and composes cleanly with NOT NULL/DEFAULT/PRIMARY KEY.
+func (d *postgresDialect) AutoIncrementModifier() string        { return "GENERATED ALWAYS AS IDENTITY" }
+func (d *postgresDialect) AutoIncrementPrimaryKeySuffix() string { return "" }
+func (d *postgresDialect) TimestampType() string                { return "TIMESTAMP" }
+
+func (d *postgresDialect) CreateTableStatement(tableName, colDefs string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", d.Quote(tableName), colDefs)
+}
+
+func (d *postgresDialect) SupportsIndexIfNotExists() bool { return true }
+func (d *postgresDialect) AddColumnClause() string        { return "ADD COLUMN" }
+
+type sqliteDialect struct{}
+
+func (d *sqliteDialect) Name() string { return "sqlite3" }
+
+func (d *sqliteDialect) DSN(cfg *TableConfig) string {
+	return cfg.DBName
+}
+
+func (d *sqliteDialect) Quote(identifier string) string {
+	return fmt.Sprintf("%q", identifier)
+}
+
+func (d *sqliteDialect) Placeholder(n int) string { return "?" }
+
+func (d *sqliteDialect) AppendParams(dsn string, params map[string]string) string {
+	if len(params) == 0 {
+		return dsn
+	}
+	return fmt.Sprintf("%s?%s", dsn, paramString(params, "&"))
+}
+
+// GenerateIDs also relies on LastInsertId, but go-sqlite3 reports the id
+// of the *last* row inserted by a multi-row VALUES list, the opposite of
+// MySQL, so the sequence has to be counted backwards from it.
+func (d *sqliteDialect) GenerateIDs(ctx context.Context, exec executor, query string, args []interface{}, primaryKey string, n int) ([]int64, error) {
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = lastID - int64(n-1) + int64(i)
+	}
+	return ids, nil
+}
+
+// AutoIncrementPrimaryKeySuffix, not AutoIncrementModifier: SQLite
+// requires AUTOINCREMENT to follow PRIMARY KEY on an INTEGER column,
+// not the column type.
+func (d *sqliteDialect) AutoIncrementModifier() string        { return "" }
+func (d *sqliteDialect) AutoIncrementPrimaryKeySuffix() string { return "AUTOINCREMENT" }
+func (d *sqliteDialect) TimestampType() string                { return "DATETIME" }
+
+func (d *sqliteDialect) CreateTableStatement(tableName, colDefs string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", d.Quote(tableName), colDefs)
+}
+
+func (d *sqliteDialect) SupportsIndexIfNotExists() bool { return true }
+func (d *sqliteDialect) AddColumnClause() string        { return "ADD COLUMN" }
+
+type mssqlDialect struct{}
+
+func (d *mssqlDialect) Name() string { return "mssql" }
+
+func (d *mssqlDialect) DSN(cfg *TableConfig) string {
+	return fmt.Sprintf("server=%s;port=%s;user id=%s;password=%s;database=%s",
+		cfg.Host,
+		cfg.Port,
+		cfg.Username,
+		cfg.Password,
+		cfg.DBName,
+	)
+}
+
+func (d *mssqlDialect) Quote(identifier string) string {
+	return fmt.Sprintf("[%s]", identifier)
+}
+
+func (d *mssqlDialect) Placeholder(n int) string {
+	return fmt.Sprintf("@p%d", n)
+}
+
+func (d *mssqlDialect) AppendParams(dsn string, params map[string]string) string {
+	if len(params) == 0 {
+		return dsn
+	}
+	return fmt.Sprintf("%s;%s", dsn, paramString(params, ";"))
+}
+
+// GenerateIDs uses an OUTPUT clause: go-mssqldb's sql.Result doesn't
+// support LastInsertId, and unlike Postgres' RETURNING, OUTPUT has to
+// sit between the column list and VALUES rather than trail the statement.
+func (d *mssqlDialect) GenerateIDs(ctx context.Context, exec executor, query string, args []interface{}, primaryKey string, n int) ([]int64, error) {
+	idx := strings.Index(query, " VALUES")
+	if idx == -1 {
+		return nil, fmt.Errorf("mssql: cannot locate VALUES clause in insert query")
+	}
+	output := fmt.Sprintf(" OUTPUT INSERTED.%s", d.Quote(primaryKey))
+	query = query[:idx] + output + query[idx:]
+	rows, err := exec.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int64, 0, n)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AutoIncrementModifier uses IDENTITY(1,1): MSSQL accepts it alongside
+// PRIMARY KEY as column-level constraints in either order.
+func (d *mssqlDialect) AutoIncrementModifier() string        { return "IDENTITY(1,1)" }
+func (d *mssqlDialect) AutoIncrementPrimaryKeySuffix() string { return "" }
+
+// TimestampType uses DATETIME2, not TIMESTAMP: on MSSQL TIMESTAMP is a
+// synonym for ROWVERSION, an auto-maintained binary value that rejects
+// explicit INSERTs, which breaks the migrations bookkeeping table.
+func (d *mssqlDialect) TimestampType() string { return "DATETIME2" }
+
+// CreateTableStatement has no IF NOT EXISTS clause to fall back on, so
+// the whole CREATE TABLE is guarded by a check against sys.tables.
+func (d *mssqlDialect) CreateTableStatement(tableName, colDefs string) string {
+	return fmt.Sprintf(
+		"IF NOT EXISTS (SELECT * FROM sys.tables WHERE name = '%s') CREATE TABLE %s (%s)",
+		tableName,
+		d.Quote(tableName),
+		colDefs,
+	)
+}
+
+// SupportsIndexIfNotExists is false: MSSQL's CREATE INDEX has no IF NOT
+// EXISTS option either.
+func (d *mssqlDialect) SupportsIndexIfNotExists() bool { return false }
+
+// AddColumnClause is just "ADD": unlike MySQL/Postgres/SQLite, T-SQL's
+// ALTER TABLE ADD doesn't take a COLUMN keyword.
+func (d *mssqlDialect) AddColumnClause() string { return "ADD" }
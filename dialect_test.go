@@ -0,0 +1,88 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+type fakeResult struct {
+	lastInsertID int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r fakeResult) RowsAffected() (int64, error) { return 0, nil }
+
+type fakeExecutor struct {
+	result sql.Result
+}
+
+func (e fakeExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.result, nil
+}
+
+func (e fakeExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, nil
+}
+
+func (e fakeExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestMySQLGenerateIDsFromFirstRow(t *testing.T) {
+	d := &mysqlDialect{}
+	exec := fakeExecutor{result: fakeResult{lastInsertID: 10}}
+	ids, err := d.GenerateIDs(context.Background(), exec, "INSERT INTO t (a) VALUES (?),(?),(?)", nil, "id", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{10, 11, 12}
+	for i, id := range ids {
+		if id != want[i] {
+			t.Fatalf("got %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestSQLiteGenerateIDsFromLastRow(t *testing.T) {
+	d := &sqliteDialect{}
+	exec := fakeExecutor{result: fakeResult{lastInsertID: 12}}
+	ids, err := d.GenerateIDs(context.Background(), exec, "INSERT INTO t (a) VALUES (?),(?),(?)", nil, "id", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{10, 11, 12}
+	for i, id := range ids {
+		if id != want[i] {
+			t.Fatalf("got %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestAutoIncrementDDLPerDialect(t *testing.T) {
+	cases := []struct {
+		dialect  Dialect
+		modifier string
+		suffix   string
+	}{
+		{&mysqlDialect{}, "AUTO_INCREMENT", ""},
+		{&postgresDialect{}, "GENERATED ALWAYS AS IDENTITY", ""},
+		{&sqliteDialect{}, "", "AUTOINCREMENT"},
+		{&mssqlDialect{}, "IDENTITY(1,1)", ""},
+	}
+	for _, c := range cases {
+		if got := c.dialect.AutoIncrementModifier(); got != c.modifier {
+			t.Errorf("%s: AutoIncrementModifier() = %q, want %q", c.dialect.Name(), got, c.modifier)
+		}
+		if got := c.dialect.AutoIncrementPrimaryKeySuffix(); got != c.suffix {
+			t.Errorf("%s: AutoIncrementPrimaryKeySuffix() = %q, want %q", c.dialect.Name(), got, c.suffix)
+		}
+	}
+}
+
+func TestTimestampTypeAvoidsMSSQLRowversion(t *testing.T) {
+	d := &mssqlDialect{}
+	if got := d.TimestampType(); got == "TIMESTAMP" {
+		t.Fatalf("mssql TimestampType() = %q, which is a ROWVERSION synonym and rejects explicit inserts", got)
+	}
+}
@@ -1,31 +1,62 @@
 package gosql
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
+// executor is satisfied by both *sql.DB and *sql.Tx, letting query
+// building be shared between the top-level Orm and Tx.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 var (
-	ErrorRowNotRecognized = fmt.Errorf("row not recognized")
-	ErrorTypeNotSupported = fmt.Errorf("type not supported")
+	ErrorRowNotRecognized    = fmt.Errorf("row not recognized")
+	ErrorTypeNotSupported    = fmt.Errorf("type not supported")
+	ErrorDialectNotSupported = fmt.Errorf("dialect not supported")
+	ErrorEmptyInValues       = fmt.Errorf("in requires at least one value")
 	// internal
-	defaultTag = "sql"
+	defaultTag     = "sql"
+	defaultDialect = "mysql"
 )
 
 type Orm interface {
 	RegisterTable(row interface{}, cfg *TableConfig) error
 	Insert(row interface{}) (lastID int64, err error)
+	InsertContext(ctx context.Context, row interface{}) (lastID int64, err error)
+	InsertMany(rows interface{}) (ids []int64, err error)
+	InsertManyContext(ctx context.Context, rows interface{}) (ids []int64, err error)
+	UpdateMany(rows interface{}) (err error)
+	UpdateManyContext(ctx context.Context, rows interface{}) (err error)
 	Update(row interface{}) (err error)
+	UpdateContext(ctx context.Context, row interface{}) (err error)
 	Select(row interface{}, query string, args ...interface{}) (rows []interface{}, err error)
+	SelectContext(ctx context.Context, row interface{}, query string, args ...interface{}) (rows []interface{}, err error)
 	Delete(row interface{}) (err error)
+	DeleteContext(ctx context.Context, row interface{}) (err error)
+	Begin(ctx context.Context, row interface{}) (*Tx, error)
+	Query(row interface{}) *Query
+	AutoMigrate(row interface{}) error
+	Migrate(row interface{}, migrations []*Migration) error
+	Rollback(row interface{}, migrations []*Migration, id int64) error
+	Stats(row interface{}) (sql.DBStats, error)
 }
 
 type TableConfig struct {
+	Driver        string
 	Host          string
 	Username      string
 	Password      string
@@ -34,13 +65,34 @@ type TableConfig struct {
 	TableName     string
 	PrimaryKey    string
 	AutoIncrement bool
-	db            *sql.DB
+	// BatchSize caps how many rows InsertMany/UpdateMany pack into a
+	// single statement. Defaults to defaultBatchSize when zero.
+	BatchSize int
+	// Pool tuning, applied to the underlying *sql.DB after it's opened.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// TLSConfig is registered with the driver (currently mysql only)
+	// and referenced from the DSN.
+	TLSConfig *tls.Config
+	// Params are extra driver-specific connection parameters merged
+	// into the DSN, e.g. {"parseTime": "true"}.
+	Params map[string]string
+	// HealthCheckInterval, if set, periodically re-pings the pool and
+	// invokes OnDisconnect when a ping fails.
+	HealthCheckInterval time.Duration
+	OnDisconnect        func(error)
+	db                  *sql.DB
+	dialect             Dialect
 }
 
 type orm struct {
 	tag      string
 	lock     sync.RWMutex
 	tableMap map[string]*TableConfig
+	dbMap    map[string]*sql.DB
+	tlsNames map[*tls.Config]string
 }
 
 func New(tag string) Orm {
@@ -50,6 +102,8 @@ func New(tag string) Orm {
 	return &orm{
 		tag:      tag,
 		tableMap: make(map[string]*TableConfig),
+		dbMap:    make(map[string]*sql.DB),
+		tlsNames: make(map[*tls.Config]string),
 	}
 }
 
@@ -87,40 +141,90 @@ func (o *orm) extractRow(row interface{}) (keys []string, values []interface{},
 func (o *orm) RegisterTable(row interface{}, cfg *TableConfig) error {
 	o.lock.Lock()
 	defer o.lock.Unlock()
-	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%s)/%s",
-		cfg.Username,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.DBName,
-	))
+	driver := cfg.Driver
+	if len(driver) == 0 {
+		driver = defaultDialect
+	}
+	dialect, err := getDialect(driver)
 	if err != nil {
 		return err
 	}
-	if err = db.Ping(); err != nil {
-		return err
+	if cfg.TLSConfig != nil {
+		if err := o.applyTLSConfig(dialect, cfg); err != nil {
+			return err
+		}
+	}
+	dsn := dialect.DSN(cfg)
+	if len(cfg.Params) > 0 {
+		dsn = dialect.AppendParams(dsn, cfg.Params)
+	}
+	dbKey := dialect.Name() + "|" + dsn
+	db, exists := o.dbMap[dbKey]
+	if !exists {
+		db, err = sql.Open(dialect.Name(), dsn)
+		if err != nil {
+			return err
+		}
+		if err = db.Ping(); err != nil {
+			return err
+		}
+		applyPoolSettings(db, cfg)
+		o.dbMap[dbKey] = db
 	}
 	cfg.db = db
+	cfg.dialect = dialect
 	typeStr := reflect.TypeOf(row).String()
 	o.tableMap[typeStr] = cfg
+	if cfg.HealthCheckInterval > 0 {
+		go o.runHealthCheck(cfg)
+	}
 	return nil
 }
 
+// Stats returns the connection pool statistics for row's registered
+// table. Tables sharing a DSN (see RegisterTable) share one pool and
+// therefore the same Stats.
+func (o *orm) Stats(row interface{}) (sql.DBStats, error) {
+	cfg, err := o.getTableConfig(row)
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return cfg.db.Stats(), nil
+}
+
 func (o *orm) Insert(row interface{}) (lastID int64, err error) {
+	return o.InsertContext(context.Background(), row)
+}
+
+func (o *orm) InsertContext(ctx context.Context, row interface{}) (lastID int64, err error) {
 	cfg, err := o.getTableConfig(row)
 	if err != nil {
 		return 0, err
 	}
+	return o.insert(ctx, cfg.db, cfg, row)
+}
+
+func (o *orm) insert(ctx context.Context, exec executor, cfg *TableConfig, row interface{}) (lastID int64, err error) {
 	keyQuery := ""
 	valueQuery := ""
 	selectedValeus := []interface{}{}
 	keys, values, _ := o.extractRow(row)
+	hooks := o.rowHooks(row)
+	now := time.Now()
+	for i, key := range keys {
+		switch key {
+		case hooks.createdCol, hooks.updatedCol:
+			values[i] = now
+		case hooks.versionCol:
+			values[i] = int64(1)
+		}
+	}
 	for i, key := range keys {
 		if cfg.AutoIncrement && cfg.PrimaryKey == key {
 			continue
 		}
-		keyQuery += fmt.Sprintf("`%s`,", key)
-		valueQuery += "?,"
+		keyQuery += fmt.Sprintf("%s,", cfg.dialect.Quote(key))
+		valueQuery += fmt.Sprintf("%s,", cfg.dialect.Placeholder(len(selectedValeus)+1))
 		selectedValeus = append(selectedValeus, values[i])
 	}
 	if len(keyQuery) > 0 {
@@ -130,81 +234,113 @@ func (o *orm) Insert(row interface{}) (lastID int64, err error) {
 		valueQuery = valueQuery[:len(valueQuery)-1]
 	}
 	query := fmt.Sprintf(
-		"INSERT INTO `%s` (%s) VALUES (%s)",
-		cfg.TableName,
+		"INSERT INTO %s (%s) VALUES (%s)",
+		cfg.dialect.Quote(cfg.TableName),
 		keyQuery,
 		valueQuery,
 	)
-	result, err := cfg.db.Exec(query, selectedValeus...)
-	if err != nil {
-		return 0, err
+	if !cfg.AutoIncrement {
+		if _, err := exec.ExecContext(ctx, query, selectedValeus...); err != nil {
+			return 0, err
+		}
+		return 0, nil
 	}
-	lastID, err = result.LastInsertId()
+	ids, err := cfg.dialect.GenerateIDs(ctx, exec, query, selectedValeus, cfg.PrimaryKey, 1)
 	if err != nil {
 		return 0, err
 	}
-	return lastID, nil
+	return ids[0], nil
 }
 
 func (o *orm) Update(row interface{}) (err error) {
+	return o.UpdateContext(context.Background(), row)
+}
+
+func (o *orm) UpdateContext(ctx context.Context, row interface{}) (err error) {
 	cfg, err := o.getTableConfig(row)
 	if err != nil {
 		return err
 	}
+	return o.update(ctx, cfg.db, cfg, row)
+}
+
+func (o *orm) update(ctx context.Context, exec executor, cfg *TableConfig, row interface{}) (err error) {
+	hooks := o.rowHooks(row)
+	keys, values, _ := o.extractRow(row)
 	keyQuery := ""
 	selectedValeus := []interface{}{}
-	var primaryValue interface{}
-	keys, values, _ := o.extractRow(row)
+	var primaryValue, oldVersion interface{}
 	for i, key := range keys {
-		if cfg.PrimaryKey == key {
+		switch {
+		case cfg.PrimaryKey == key:
 			primaryValue = values[i]
-		} else {
-			keyQuery += fmt.Sprintf("`%s`=?,", key)
+		case len(hooks.updatedCol) > 0 && key == hooks.updatedCol:
+			keyQuery += fmt.Sprintf("%s=%s,", cfg.dialect.Quote(key), cfg.dialect.Placeholder(len(selectedValeus)+1))
+			selectedValeus = append(selectedValeus, time.Now())
+		case hooks.hasVersion() && key == hooks.versionCol:
+			oldVersion = values[i]
+			keyQuery += fmt.Sprintf("%s=%s,", cfg.dialect.Quote(key), cfg.dialect.Placeholder(len(selectedValeus)+1))
+			selectedValeus = append(selectedValeus, bumpVersion(oldVersion))
+		default:
+			keyQuery += fmt.Sprintf("%s=%s,", cfg.dialect.Quote(key), cfg.dialect.Placeholder(len(selectedValeus)+1))
 			selectedValeus = append(selectedValeus, values[i])
 		}
 	}
 	if len(keyQuery) > 0 {
 		keyQuery = keyQuery[:len(keyQuery)-1]
 	}
+	where := fmt.Sprintf("%s=%s", cfg.dialect.Quote(cfg.PrimaryKey), cfg.dialect.Placeholder(len(selectedValeus)+1))
+	selectedValeus = append(selectedValeus, primaryValue)
+	if hooks.hasVersion() {
+		where += fmt.Sprintf(" AND %s=%s", cfg.dialect.Quote(hooks.versionCol), cfg.dialect.Placeholder(len(selectedValeus)+1))
+		selectedValeus = append(selectedValeus, oldVersion)
+	}
 	query := fmt.Sprintf(
-		"UPDATE `%s` SET %s WHERE %s=?",
-		cfg.TableName,
+		"UPDATE %s SET %s WHERE %s",
+		cfg.dialect.Quote(cfg.TableName),
 		keyQuery,
-		cfg.PrimaryKey,
+		where,
 	)
-	selectedValeus = append(selectedValeus, primaryValue)
-	if _, err = cfg.db.Exec(query, selectedValeus...); err != nil {
+	result, err := exec.ExecContext(ctx, query, selectedValeus...)
+	if err != nil {
 		return err
 	}
+	if hooks.hasVersion() {
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrorStaleObject
+		}
+	}
 	return nil
 }
 
 func (o *orm) Select(row interface{}, query string, args ...interface{}) (rows []interface{}, err error) {
+	return o.SelectContext(context.Background(), row, query, args...)
+}
+
+func (o *orm) SelectContext(ctx context.Context, row interface{}, query string, args ...interface{}) (rows []interface{}, err error) {
 	cfg, err := o.getTableConfig(row)
 	if err != nil {
 		return nil, err
 	}
-	keyQuery := ""
+	return o.selectRows(ctx, cfg.db, cfg, row, query, args...)
+}
+
+func (o *orm) selectColumns(cfg *TableConfig, row interface{}) (keyQuery string, colMap map[int]bool) {
 	keys, _, colMap := o.extractRow(row)
 	for _, key := range keys {
-		keyQuery += fmt.Sprintf("`%s`,", key)
+		keyQuery += fmt.Sprintf("%s,", cfg.dialect.Quote(key))
 	}
 	if len(keyQuery) > 0 {
 		keyQuery = keyQuery[:len(keyQuery)-1]
 	}
-	if len(query) == 0 {
-		query = "TRUE"
-	}
-	sqlQuery := fmt.Sprintf(
-		"SELECT %s FROM `%s` WHERE %s",
-		keyQuery,
-		cfg.TableName,
-		query,
-	)
-	sqlRows, err := cfg.db.Query(sqlQuery, args...)
-	if err != nil {
-		return nil, err
-	}
+	return keyQuery, colMap
+}
+
+func (o *orm) scanRows(row interface{}, colMap map[int]bool, sqlRows *sql.Rows) (rows []interface{}, err error) {
 	v := reflect.ValueOf(row).Elem()
 	for sqlRows.Next() {
 		var dest []interface{}
@@ -232,11 +368,40 @@ func (o *orm) Select(row interface{}, query string, args ...interface{}) (rows [
 	return rows, nil
 }
 
+func (o *orm) selectRows(ctx context.Context, exec executor, cfg *TableConfig, row interface{}, query string, args ...interface{}) (rows []interface{}, err error) {
+	keyQuery, colMap := o.selectColumns(cfg, row)
+	if len(query) == 0 {
+		query = "TRUE"
+	}
+	if hooks := o.rowHooks(row); hooks.hasDeleted() {
+		query = scopeDeleted(cfg.dialect, hooks.deletedCol, query)
+	}
+	sqlQuery := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s",
+		keyQuery,
+		cfg.dialect.Quote(cfg.TableName),
+		query,
+	)
+	sqlRows, err := exec.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	return o.scanRows(row, colMap, sqlRows)
+}
+
 func (o *orm) Delete(row interface{}) (err error) {
+	return o.DeleteContext(context.Background(), row)
+}
+
+func (o *orm) DeleteContext(ctx context.Context, row interface{}) (err error) {
 	cfg, err := o.getTableConfig(row)
 	if err != nil {
 		return err
 	}
+	return o.delete(ctx, cfg.db, cfg, row)
+}
+
+func (o *orm) delete(ctx context.Context, exec executor, cfg *TableConfig, row interface{}) (err error) {
 	var primaryValue interface{}
 	keys, values, _ := o.extractRow(row)
 	for i, key := range keys {
@@ -245,12 +410,26 @@ func (o *orm) Delete(row interface{}) (err error) {
 			break
 		}
 	}
+	hooks := o.rowHooks(row)
+	if hooks.hasDeleted() {
+		query := fmt.Sprintf(
+			"UPDATE %s SET %s=%s WHERE %s=%s",
+			cfg.dialect.Quote(cfg.TableName),
+			cfg.dialect.Quote(hooks.deletedCol),
+			cfg.dialect.Placeholder(1),
+			cfg.dialect.Quote(cfg.PrimaryKey),
+			cfg.dialect.Placeholder(2),
+		)
+		_, err = exec.ExecContext(ctx, query, time.Now(), primaryValue)
+		return err
+	}
 	query := fmt.Sprintf(
-		"DELETE FROM `%s` WHERE `%s`=?",
-		cfg.TableName,
-		cfg.PrimaryKey,
+		"DELETE FROM %s WHERE %s=%s",
+		cfg.dialect.Quote(cfg.TableName),
+		cfg.dialect.Quote(cfg.PrimaryKey),
+		cfg.dialect.Placeholder(1),
 	)
-	if _, err = cfg.db.Exec(query, primaryValue); err != nil {
+	if _, err = exec.ExecContext(ctx, query, primaryValue); err != nil {
 		return err
 	}
 	return nil
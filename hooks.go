@@ -0,0 +1,58 @@
+package gosql
+
+import "fmt"
+
+// ErrorStaleObject is returned by Update when a row's version column
+// doesn't match the database, meaning another writer updated it first.
+var ErrorStaleObject = fmt.Errorf("stale object")
+
+// rowHooks names the columns, if any, recognized from the created,
+// updated, deleted and version tag modifiers on a registered row type.
+type rowHooks struct {
+	createdCol string
+	updatedCol string
+	deletedCol string
+	versionCol string
+}
+
+func (o *orm) rowHooks(row interface{}) rowHooks {
+	var h rowHooks
+	for _, spec := range o.columnSpecs(row) {
+		switch {
+		case spec.Created:
+			h.createdCol = spec.Name
+		case spec.Updated:
+			h.updatedCol = spec.Name
+		case spec.Deleted:
+			h.deletedCol = spec.Name
+		case spec.Version:
+			h.versionCol = spec.Name
+		}
+	}
+	return h
+}
+
+func (h rowHooks) hasDeleted() bool { return len(h.deletedCol) > 0 }
+func (h rowHooks) hasVersion() bool { return len(h.versionCol) > 0 }
+
+// scopeDeleted appends "AND deletedCol IS NULL" to a WHERE clause so
+// Select skips soft-deleted rows by default.
+func scopeDeleted(dialect Dialect, deletedCol, where string) string {
+	return fmt.Sprintf("(%s) AND %s IS NULL", where, dialect.Quote(deletedCol))
+}
+
+// bumpVersion increments an optimistic-locking version value. int, int32
+// and int64 values are incremented and returned as int64; values of any
+// other type, including other integer types, are returned unchanged.
+func bumpVersion(v interface{}) interface{} {
+	switch n := v.(type) {
+	case int:
+		return int64(n) + 1
+	case int32:
+		return int64(n) + 1
+	case int64:
+		return n + 1
+	default:
+		return v
+	}
+}
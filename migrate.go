@@ -0,0 +1,341 @@
+package gosql
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorMigrationNotFound = fmt.Errorf("migration not found")
+	// internal
+	migrationsTable = "gosql_migrations"
+)
+
+// Migration is a schema change keyed by a numeric ID that can't be
+// inferred from a struct's tags. Up is applied by Migrate, Down by
+// Rollback. Applied IDs are persisted so restarts skip completed steps.
+type Migration struct {
+	ID   int64
+	Up   func(*sql.DB) error
+	Down func(*sql.DB) error
+}
+
+// columnSpec is the parsed form of a struct field's sql tag, extended
+// with type, nullability, default, unique and index hints, e.g.
+// `sql:"email,varchar(255),notnull,unique"`. The created/updated/deleted/
+// version modifiers mark columns gosql stamps automatically; see hooks.go.
+type columnSpec struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Unique   bool
+	Index    bool
+	Default  string
+	Created  bool
+	Updated  bool
+	Deleted  bool
+	Version  bool
+}
+
+func parseColumnSpec(tag string) *columnSpec {
+	parts := strings.Split(tag, ",")
+	if len(parts) == 0 || parts[0] == "-" || len(parts[0]) == 0 {
+		return nil
+	}
+	spec := &columnSpec{
+		Name:     parts[0],
+		Type:     "TEXT",
+		Nullable: true,
+	}
+	for _, part := range parts[1:] {
+		mod := strings.TrimSpace(part)
+		switch {
+		case mod == "notnull":
+			spec.Nullable = false
+		case mod == "unique":
+			spec.Unique = true
+		case mod == "index":
+			spec.Index = true
+		case mod == "created":
+			spec.Created = true
+		case mod == "updated":
+			spec.Updated = true
+		case mod == "deleted":
+			spec.Deleted = true
+		case mod == "version":
+			spec.Version = true
+		case strings.HasPrefix(mod, "default="):
+			spec.Default = strings.TrimPrefix(mod, "default=")
+		case len(mod) > 0:
+			spec.Type = mod
+		}
+	}
+	return spec
+}
+
+func (o *orm) columnSpecs(row interface{}) []*columnSpec {
+	v := reflect.Indirect(reflect.ValueOf(row))
+	t := v.Type()
+	var specs []*columnSpec
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get(o.tag)
+		if len(tag) == 0 {
+			continue
+		}
+		if spec := parseColumnSpec(tag); spec != nil {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// AutoMigrate inspects row's struct fields and their sql tags and
+// creates the mapped table if it doesn't exist, then adds any columns
+// that are missing from an existing table.
+func (o *orm) AutoMigrate(row interface{}) error {
+	cfg, err := o.getTableConfig(row)
+	if err != nil {
+		return err
+	}
+	specs := o.columnSpecs(row)
+	if err := o.createTable(cfg, specs); err != nil {
+		return err
+	}
+	return o.addMissingColumns(cfg, specs)
+}
+
+// createTableQuery builds the CREATE TABLE statement for tableName from
+// specs, pulled out of createTable as a pure function so the generated
+// SQL text can be checked per dialect without a live connection.
+func createTableQuery(dialect Dialect, tableName, primaryKey string, autoIncrement bool, specs []*columnSpec) string {
+	colDefs := ""
+	for _, spec := range specs {
+		colDefs += fmt.Sprintf("%s %s", dialect.Quote(spec.Name), spec.Type)
+		if spec.Name == primaryKey && autoIncrement {
+			if mod := dialect.AutoIncrementModifier(); len(mod) > 0 {
+				colDefs += " " + mod
+			}
+		}
+		if !spec.Nullable {
+			colDefs += " NOT NULL"
+		}
+		if len(spec.Default) > 0 {
+			colDefs += fmt.Sprintf(" DEFAULT %s", spec.Default)
+		}
+		if spec.Name == primaryKey {
+			colDefs += " PRIMARY KEY"
+			if autoIncrement {
+				if suffix := dialect.AutoIncrementPrimaryKeySuffix(); len(suffix) > 0 {
+					colDefs += " " + suffix
+				}
+			}
+		}
+		colDefs += ","
+	}
+	colDefs = strings.TrimSuffix(colDefs, ",")
+	return dialect.CreateTableStatement(tableName, colDefs)
+}
+
+func (o *orm) createTable(cfg *TableConfig, specs []*columnSpec) error {
+	query := createTableQuery(cfg.dialect, cfg.TableName, cfg.PrimaryKey, cfg.AutoIncrement, specs)
+	if _, err := cfg.db.Exec(query); err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		if spec.Name == cfg.PrimaryKey {
+			continue
+		}
+		if err := o.createIndex(cfg, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexQuery builds the CREATE INDEX statement for spec, pulled
+// out of createIndex as a pure function for the same reason as
+// createTableQuery.
+func createIndexQuery(dialect Dialect, tableName string, spec *columnSpec) string {
+	unique := ""
+	if spec.Unique {
+		unique = "UNIQUE "
+	}
+	ifNotExists := ""
+	if dialect.SupportsIndexIfNotExists() {
+		ifNotExists = "IF NOT EXISTS "
+	}
+	indexName := fmt.Sprintf("idx_%s_%s", tableName, spec.Name)
+	return fmt.Sprintf(
+		"CREATE %sINDEX %s%s ON %s (%s)",
+		unique,
+		ifNotExists,
+		dialect.Quote(indexName),
+		dialect.Quote(tableName),
+		dialect.Quote(spec.Name),
+	)
+}
+
+func (o *orm) createIndex(cfg *TableConfig, spec *columnSpec) error {
+	if !spec.Unique && !spec.Index {
+		return nil
+	}
+	query := createIndexQuery(cfg.dialect, cfg.TableName, spec)
+	if _, err := cfg.db.Exec(query); err != nil && !isDuplicateIndexError(err) {
+		return err
+	}
+	return nil
+}
+
+// addColumnQuery builds the ALTER TABLE statement that introduces spec
+// as a new column, pulled out of addMissingColumns as a pure function
+// for the same reason as createTableQuery.
+func addColumnQuery(dialect Dialect, tableName string, spec *columnSpec) string {
+	colDef := fmt.Sprintf("%s %s", dialect.Quote(spec.Name), spec.Type)
+	if !spec.Nullable {
+		colDef += " NOT NULL"
+	}
+	if len(spec.Default) > 0 {
+		colDef += fmt.Sprintf(" DEFAULT %s", spec.Default)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s %s", dialect.Quote(tableName), dialect.AddColumnClause(), colDef)
+}
+
+// addMissingColumns attempts to ALTER TABLE in a column for every spec.
+// Backends reject adding a column that already exists; that error is
+// treated as a no-op so AutoMigrate stays idempotent across restarts.
+func (o *orm) addMissingColumns(cfg *TableConfig, specs []*columnSpec) error {
+	for _, spec := range specs {
+		query := addColumnQuery(cfg.dialect, cfg.TableName, spec)
+		if _, err := cfg.db.Exec(query); err != nil && !isDuplicateColumnError(err) {
+			return err
+		}
+		if err := o.createIndex(cfg, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isDuplicateColumnError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate column") ||
+		strings.Contains(msg, "already exists") ||
+		strings.Contains(msg, "specified more than once")
+}
+
+func isDuplicateIndexError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "duplicate key name") ||
+		strings.Contains(msg, "already exists") ||
+		strings.Contains(msg, "already an index")
+}
+
+func (o *orm) ensureMigrationsTable(cfg *TableConfig) error {
+	colDefs := fmt.Sprintf(
+		"%s BIGINT PRIMARY KEY, %s %s",
+		cfg.dialect.Quote("id"),
+		cfg.dialect.Quote("applied_at"),
+		cfg.dialect.TimestampType(),
+	)
+	query := cfg.dialect.CreateTableStatement(migrationsTable, colDefs)
+	_, err := cfg.db.Exec(query)
+	return err
+}
+
+func (o *orm) appliedMigrationIDs(cfg *TableConfig) (map[int64]bool, error) {
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s",
+		cfg.dialect.Quote("id"),
+		cfg.dialect.Quote(migrationsTable),
+	)
+	rows, err := cfg.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+	return applied, nil
+}
+
+// Migrate applies every migration whose ID hasn't been recorded yet, in
+// ascending ID order, and records each successful ID so restarts skip it.
+func (o *orm) Migrate(row interface{}, migrations []*Migration) error {
+	cfg, err := o.getTableConfig(row)
+	if err != nil {
+		return err
+	}
+	if err := o.ensureMigrationsTable(cfg); err != nil {
+		return err
+	}
+	applied, err := o.appliedMigrationIDs(cfg)
+	if err != nil {
+		return err
+	}
+	ordered := append([]*Migration{}, migrations...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ID < ordered[j].ID })
+	for _, m := range ordered {
+		if applied[m.ID] {
+			continue
+		}
+		if m.Up != nil {
+			if err := m.Up(cfg.db); err != nil {
+				return err
+			}
+		}
+		insertQuery := fmt.Sprintf(
+			"INSERT INTO %s (%s, %s) VALUES (%s, %s)",
+			cfg.dialect.Quote(migrationsTable),
+			cfg.dialect.Quote("id"),
+			cfg.dialect.Quote("applied_at"),
+			cfg.dialect.Placeholder(1),
+			cfg.dialect.Placeholder(2),
+		)
+		if _, err := cfg.db.Exec(insertQuery, m.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback runs the Down func of the migration matching id and removes
+// it from the bookkeeping table so it can be re-applied by Migrate.
+func (o *orm) Rollback(row interface{}, migrations []*Migration, id int64) error {
+	cfg, err := o.getTableConfig(row)
+	if err != nil {
+		return err
+	}
+	var target *Migration
+	for _, m := range migrations {
+		if m.ID == id {
+			target = m
+			break
+		}
+	}
+	if target == nil {
+		return ErrorMigrationNotFound
+	}
+	if target.Down != nil {
+		if err := target.Down(cfg.db); err != nil {
+			return err
+		}
+	}
+	deleteQuery := fmt.Sprintf(
+		"DELETE FROM %s WHERE %s=%s",
+		cfg.dialect.Quote(migrationsTable),
+		cfg.dialect.Quote("id"),
+		cfg.dialect.Placeholder(1),
+	)
+	_, err = cfg.db.Exec(deleteQuery, id)
+	return err
+}
@@ -0,0 +1,64 @@
+package gosql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateTableQueryPerDialect(t *testing.T) {
+	specs := []*columnSpec{
+		{Name: "id", Type: "BIGINT", Nullable: false},
+		{Name: "name", Type: "VARCHAR(255)", Nullable: true},
+	}
+
+	mysql, _ := getDialect("mysql")
+	if got := createTableQuery(mysql, "users", "id", true, specs); !strings.Contains(got, "CREATE TABLE IF NOT EXISTS") || !strings.Contains(got, "AUTO_INCREMENT") {
+		t.Fatalf("mysql: unexpected query: %s", got)
+	}
+
+	mssql, _ := getDialect("mssql")
+	got := createTableQuery(mssql, "users", "id", true, specs)
+	if strings.Contains(got, "IF NOT EXISTS (") == false || strings.Contains(got, "CREATE TABLE IF NOT EXISTS") {
+		t.Fatalf("mssql: expected a sys.tables existence check, not IF NOT EXISTS, got: %s", got)
+	}
+	if !strings.Contains(got, "IDENTITY(1,1)") {
+		t.Fatalf("mssql: expected IDENTITY(1,1), got: %s", got)
+	}
+}
+
+func TestCreateIndexQueryPerDialect(t *testing.T) {
+	spec := &columnSpec{Name: "email", Unique: true}
+
+	mysql, _ := getDialect("mysql")
+	if got := createIndexQuery(mysql, "users", spec); strings.Contains(got, "IF NOT EXISTS") {
+		t.Fatalf("mysql: CREATE INDEX has no IF NOT EXISTS, got: %s", got)
+	}
+
+	mssql, _ := getDialect("mssql")
+	if got := createIndexQuery(mssql, "users", spec); strings.Contains(got, "IF NOT EXISTS") {
+		t.Fatalf("mssql: CREATE INDEX has no IF NOT EXISTS, got: %s", got)
+	}
+
+	postgres, _ := getDialect("postgres")
+	if got := createIndexQuery(postgres, "users", spec); !strings.Contains(got, "IF NOT EXISTS") {
+		t.Fatalf("postgres: expected IF NOT EXISTS, got: %s", got)
+	}
+}
+
+func TestAddColumnQueryPerDialect(t *testing.T) {
+	spec := &columnSpec{Name: "age", Type: "INT", Nullable: true}
+
+	mysql, _ := getDialect("mysql")
+	if got := addColumnQuery(mysql, "users", spec); !strings.Contains(got, "ADD COLUMN") {
+		t.Fatalf("mysql: expected ADD COLUMN, got: %s", got)
+	}
+
+	mssql, _ := getDialect("mssql")
+	got := addColumnQuery(mssql, "users", spec)
+	if strings.Contains(got, "ADD COLUMN") {
+		t.Fatalf("mssql: ALTER TABLE ADD doesn't take COLUMN, got: %s", got)
+	}
+	if !strings.Contains(got, "ADD ") {
+		t.Fatalf("mssql: expected bare ADD clause, got: %s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package gosql
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+)
+
+var tlsConfigSeq int64
+
+// applyTLSConfig registers cfg.TLSConfig with the driver and points the
+// DSN at it via cfg.Params. Only the mysql driver supports named,
+// pre-registered tls.Config values; for other dialects cfg is left
+// untouched and TLSConfig is not used by their DSN.
+//
+// Registration is cached by *tls.Config identity on o.tlsNames: calling
+// RegisterTable repeatedly with the same TLSConfig value must produce
+// the same registered name (and so the same DSN), or those tables would
+// never compute a matching dbKey and shared-DB mode could never engage
+// for TLS-enabled configs. It also keeps a process-lifetime registration
+// leak to one entry per distinct TLSConfig instead of one per call.
+// Must be called with o.lock held, as RegisterTable already does.
+func (o *orm) applyTLSConfig(dialect Dialect, cfg *TableConfig) error {
+	if dialect.Name() != "mysql" {
+		return nil
+	}
+	name, exists := o.tlsNames[cfg.TLSConfig]
+	if !exists {
+		name = fmt.Sprintf("gosql-%d", atomic.AddInt64(&tlsConfigSeq, 1))
+		if err := mysqldriver.RegisterTLSConfig(name, cfg.TLSConfig); err != nil {
+			return err
+		}
+		o.tlsNames[cfg.TLSConfig] = name
+	}
+	if cfg.Params == nil {
+		cfg.Params = make(map[string]string)
+	}
+	cfg.Params["tls"] = name
+	return nil
+}
+
+func applyPoolSettings(db *sql.DB, cfg *TableConfig) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// runHealthCheck re-pings cfg's pool every HealthCheckInterval for the
+// lifetime of the process, invoking OnDisconnect whenever a ping fails.
+func (o *orm) runHealthCheck(cfg *TableConfig) {
+	ticker := time.NewTicker(cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := cfg.db.Ping(); err != nil && cfg.OnDisconnect != nil {
+			cfg.OnDisconnect(err)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package gosql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Cond is a single comparison used to build a grouped Or clause, e.g.
+// NewCond("status", "=", "active").
+type Cond struct {
+	Column string
+	Op     string
+	Value  interface{}
+}
+
+// NewCond builds a Cond for use with Query.Or.
+func NewCond(column, op string, value interface{}) *Cond {
+	return &Cond{Column: column, Op: op, Value: value}
+}
+
+// Query is a fluent, dialect-aware builder for parameterized SELECT
+// statements, returned by Orm.Query. It's an alternative to the raw
+// WHERE-string Select for the common cases; Select remains available as
+// an escape hatch for anything the builder doesn't cover.
+type Query struct {
+	o    *orm
+	cfg  *TableConfig
+	row  interface{}
+	err  error
+	ctx  context.Context
+	exec executor
+
+	wheres     []string
+	args       []interface{}
+	joins      []string
+	groupBy    []string
+	having     string
+	havingArgs []interface{}
+	orderBy    string
+	limit      *int
+	offset     *int
+	unscoped   bool
+}
+
+// Query starts a builder for row's registered table, running Find/First/
+// Count against the top-level *sql.DB. Use Tx.Query to run the same
+// builder inside a transaction instead.
+func (o *orm) Query(row interface{}) *Query {
+	cfg, err := o.getTableConfig(row)
+	var exec executor
+	if cfg != nil {
+		exec = cfg.db
+	}
+	return &Query{o: o, cfg: cfg, row: row, err: err, ctx: context.Background(), exec: exec}
+}
+
+// Unscoped disables the automatic "deleted_at IS NULL" filter that's
+// otherwise applied for rows with a soft-delete column.
+func (q *Query) Unscoped() *Query {
+	q.unscoped = true
+	return q
+}
+
+// Context sets the context used by Find, First and Count, in place of
+// the context.Background() a builder starts with.
+func (q *Query) Context(ctx context.Context) *Query {
+	q.ctx = ctx
+	return q
+}
+
+func (q *Query) appendWhere(boolOp, clause string, args ...interface{}) *Query {
+	if len(q.wheres) > 0 {
+		clause = boolOp + " " + clause
+	}
+	q.wheres = append(q.wheres, clause)
+	q.args = append(q.args, args...)
+	return q
+}
+
+// Where starts (or resets) the condition chain with col op value.
+func (q *Query) Where(column, op string, value interface{}) *Query {
+	q.wheres = nil
+	q.args = nil
+	return q.And(column, op, value)
+}
+
+// And appends an AND-ed col op value condition.
+func (q *Query) And(column, op string, value interface{}) *Query {
+	if q.err != nil {
+		return q
+	}
+	clause := fmt.Sprintf("%s%s%s", q.cfg.dialect.Quote(column), op, q.placeholder())
+	return q.appendWhere("AND", clause, value)
+}
+
+// Or appends a parenthesized group of conditions joined by OR.
+func (q *Query) Or(conds ...*Cond) *Query {
+	if q.err != nil || len(conds) == 0 {
+		return q
+	}
+	base := len(q.args)
+	group := ""
+	args := make([]interface{}, 0, len(conds))
+	for i, c := range conds {
+		if i > 0 {
+			group += " OR "
+		}
+		group += fmt.Sprintf("%s%s%s", q.cfg.dialect.Quote(c.Column), c.Op, q.cfg.dialect.Placeholder(base+i+1))
+		args = append(args, c.Value)
+	}
+	return q.appendWhere("AND", "("+group+")", args...)
+}
+
+// In appends an AND-ed col IN (...) condition. values must be non-empty;
+// "col IN ()" is a syntax error on every supported backend.
+func (q *Query) In(column string, values ...interface{}) *Query {
+	if q.err != nil {
+		return q
+	}
+	if len(values) == 0 {
+		q.err = ErrorEmptyInValues
+		return q
+	}
+	base := len(q.args)
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = q.cfg.dialect.Placeholder(base + i + 1)
+	}
+	clause := fmt.Sprintf("%s IN (%s)", q.cfg.dialect.Quote(column), strings.Join(placeholders, ","))
+	return q.appendWhere("AND", clause, values...)
+}
+
+// Like appends an AND-ed col LIKE pattern condition.
+func (q *Query) Like(column, pattern string) *Query {
+	if q.err != nil {
+		return q
+	}
+	clause := fmt.Sprintf("%s LIKE %s", q.cfg.dialect.Quote(column), q.placeholder())
+	return q.appendWhere("AND", clause, pattern)
+}
+
+// IsNull appends an AND-ed col IS NULL condition.
+func (q *Query) IsNull(column string) *Query {
+	if q.err != nil {
+		return q
+	}
+	clause := fmt.Sprintf("%s IS NULL", q.cfg.dialect.Quote(column))
+	return q.appendWhere("AND", clause)
+}
+
+// Join appends a raw JOIN clause, e.g. "INNER JOIN orders ON orders.user_id = users.id".
+func (q *Query) Join(clause string) *Query {
+	q.joins = append(q.joins, clause)
+	return q
+}
+
+// GroupBy sets the GROUP BY columns.
+func (q *Query) GroupBy(columns ...string) *Query {
+	q.groupBy = columns
+	return q
+}
+
+// Having sets a raw HAVING clause, parameterized like Select's WHERE.
+func (q *Query) Having(clause string, args ...interface{}) *Query {
+	q.having = clause
+	q.havingArgs = args
+	return q
+}
+
+// OrderBy sets ORDER BY column direction, e.g. OrderBy("id", "DESC").
+func (q *Query) OrderBy(column, direction string) *Query {
+	q.orderBy = fmt.Sprintf("%s %s", q.cfg.dialect.Quote(column), direction)
+	return q
+}
+
+// Limit sets LIMIT n.
+func (q *Query) Limit(n int) *Query {
+	q.limit = &n
+	return q
+}
+
+// Offset sets OFFSET n.
+func (q *Query) Offset(n int) *Query {
+	q.offset = &n
+	return q
+}
+
+func (q *Query) placeholder() string {
+	return q.cfg.dialect.Placeholder(len(q.args) + 1)
+}
+
+func (q *Query) build(selectList string) (string, []interface{}) {
+	query := fmt.Sprintf("SELECT %s FROM %s", selectList, q.cfg.dialect.Quote(q.cfg.TableName))
+	for _, join := range q.joins {
+		query += " " + join
+	}
+	where := "TRUE"
+	if len(q.wheres) > 0 {
+		where = strings.Join(q.wheres, " ")
+	}
+	if !q.unscoped {
+		if hooks := q.o.rowHooks(q.row); hooks.hasDeleted() {
+			where = scopeDeleted(q.cfg.dialect, hooks.deletedCol, where)
+		}
+	}
+	query += " WHERE " + where
+	args := append([]interface{}{}, q.args...)
+	if len(q.groupBy) > 0 {
+		quoted := make([]string, len(q.groupBy))
+		for i, col := range q.groupBy {
+			quoted[i] = q.cfg.dialect.Quote(col)
+		}
+		query += " GROUP BY " + strings.Join(quoted, ",")
+	}
+	if len(q.having) > 0 {
+		query += " HAVING " + q.having
+		args = append(args, q.havingArgs...)
+	}
+	if len(q.orderBy) > 0 {
+		query += " ORDER BY " + q.orderBy
+	}
+	if q.limit != nil {
+		query += fmt.Sprintf(" LIMIT %d", *q.limit)
+	}
+	if q.offset != nil {
+		query += fmt.Sprintf(" OFFSET %d", *q.offset)
+	}
+	return query, args
+}
+
+// Find executes the built query and returns the matching rows.
+func (q *Query) Find() (rows []interface{}, err error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	selectList, colMap := q.o.selectColumns(q.cfg, q.row)
+	sqlQuery, args := q.build(selectList)
+	sqlRows, err := q.exec.QueryContext(q.ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	return q.o.scanRows(q.row, colMap, sqlRows)
+}
+
+// First executes the built query with LIMIT 1 and returns the first
+// match, or nil if there is none.
+func (q *Query) First() (interface{}, error) {
+	q.Limit(1)
+	rows, err := q.Find()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// Count executes the built query as a COUNT(*) and returns the total.
+func (q *Query) Count() (int64, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
+	sqlQuery, args := q.build("COUNT(*)")
+	var count int64
+	row := q.exec.QueryRowContext(q.ctx, sqlQuery, args...)
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
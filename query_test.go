@@ -0,0 +1,50 @@
+package gosql
+
+import (
+	"strings"
+	"testing"
+)
+
+type queryTestRow struct {
+	ID   int64  `sql:"id"`
+	Name string `sql:"name"`
+}
+
+func newTestQuery(dialectName string) *Query {
+	o := &orm{tag: "sql"}
+	dialect, _ := getDialect(dialectName)
+	cfg := &TableConfig{TableName: "items", dialect: dialect}
+	return &Query{o: o, cfg: cfg, row: &queryTestRow{}}
+}
+
+func TestQueryInPlaceholders(t *testing.T) {
+	q := newTestQuery("postgres")
+	q.Where("name", "=", "x").In("id", 1, 2, 3)
+	sqlQuery, args := q.build("*")
+	if !strings.Contains(sqlQuery, "IN ($2,$3,$4)") {
+		t.Fatalf("expected distinct placeholders $2,$3,$4 in query, got: %s", sqlQuery)
+	}
+	if len(args) != 4 {
+		t.Fatalf("expected 4 args, got %d: %v", len(args), args)
+	}
+}
+
+func TestQueryInRejectsEmptyValues(t *testing.T) {
+	q := newTestQuery("postgres")
+	q.In("id")
+	if q.err != ErrorEmptyInValues {
+		t.Fatalf("expected ErrorEmptyInValues, got: %v", q.err)
+	}
+}
+
+func TestQueryOrPlaceholders(t *testing.T) {
+	q := newTestQuery("postgres")
+	q.Where("name", "=", "x").Or(NewCond("a", "=", 1), NewCond("b", "=", 2))
+	sqlQuery, args := q.build("*")
+	if !strings.Contains(sqlQuery, `"a"=$2`) || !strings.Contains(sqlQuery, `"b"=$3`) {
+		t.Fatalf("expected distinct placeholders $2,$3 in query, got: %s", sqlQuery)
+	}
+	if len(args) != 3 {
+		t.Fatalf("expected 3 args, got %d: %v", len(args), args)
+	}
+}
@@ -0,0 +1,82 @@
+package gosql
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Tx is a transaction handle returned by Orm.Begin. It exposes the same
+// CRUD surface as Orm, scoped to the underlying *sql.Tx, so callers can
+// group multiple operations into a single atomic unit of work.
+type Tx struct {
+	o   *orm
+	ctx context.Context
+	tx  *sql.Tx
+}
+
+// Begin starts a transaction on the *sql.DB backing row's registered
+// table and returns a Tx implementing Insert, Update, Select and Delete
+// against it.
+func (o *orm) Begin(ctx context.Context, row interface{}) (*Tx, error) {
+	cfg, err := o.getTableConfig(row)
+	if err != nil {
+		return nil, err
+	}
+	sqlTx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{o: o, ctx: ctx, tx: sqlTx}, nil
+}
+
+func (t *Tx) Insert(row interface{}) (lastID int64, err error) {
+	cfg, err := t.o.getTableConfig(row)
+	if err != nil {
+		return 0, err
+	}
+	return t.o.insert(t.ctx, t.tx, cfg, row)
+}
+
+func (t *Tx) Update(row interface{}) (err error) {
+	cfg, err := t.o.getTableConfig(row)
+	if err != nil {
+		return err
+	}
+	return t.o.update(t.ctx, t.tx, cfg, row)
+}
+
+func (t *Tx) Select(row interface{}, query string, args ...interface{}) (rows []interface{}, err error) {
+	cfg, err := t.o.getTableConfig(row)
+	if err != nil {
+		return nil, err
+	}
+	return t.o.selectRows(t.ctx, t.tx, cfg, row, query, args...)
+}
+
+func (t *Tx) Delete(row interface{}) (err error) {
+	cfg, err := t.o.getTableConfig(row)
+	if err != nil {
+		return err
+	}
+	return t.o.delete(t.ctx, t.tx, cfg, row)
+}
+
+// Query starts a builder for row's registered table scoped to the
+// transaction, so Find/First/Count run against t.tx instead of opening a
+// connection outside it.
+func (t *Tx) Query(row interface{}) *Query {
+	cfg, err := t.o.getTableConfig(row)
+	var exec executor
+	if cfg != nil {
+		exec = t.tx
+	}
+	return &Query{o: t.o, cfg: cfg, row: row, err: err, ctx: t.ctx, exec: exec}
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}